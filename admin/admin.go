@@ -0,0 +1,200 @@
+// Package admin implements a REST API for record CRUD and zone inspection,
+// for programmatic zone management without a TSIG'd nsupdate client. It
+// drives the same zone.Zone.ApplyUpdate path a DNS UPDATE request does, so
+// both leave the backend in identical state.
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/yseto/dynamic-dns-go/zone"
+)
+
+// Handler returns an http.Handler serving the admin REST API over zones,
+// keyed by zone apex name (e.g. "example.com."). Requests are authorized by
+// requireBearer; when tokens is empty, authorization is left entirely to the
+// listener's transport (e.g. mTLS), mirroring the "allow-cidr" gating that
+// already exists for DNS UPDATE.
+func Handler(zones map[string]*zone.Zone, tokens []string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /zones", listZones(zones))
+	mux.HandleFunc("GET /zones/{zone}/records", listRecords(zones))
+	mux.HandleFunc("POST /zones/{zone}/records", createRecord(zones))
+	mux.HandleFunc("DELETE /zones/{zone}/records/{name}/{type}", deleteRecord(zones))
+	mux.HandleFunc("GET /zones/{zone}/export", exportZone(zones))
+
+	return requireBearer(tokens, mux)
+}
+
+func lookupZone(zones map[string]*zone.Zone, r *http.Request) (*zone.Zone, bool) {
+	z, ok := zones[dns.Fqdn(r.PathValue("zone"))]
+	return z, ok
+}
+
+func listZones(zones map[string]*zone.Zone) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		names := make([]string, 0, len(zones))
+		for name := range zones {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeJSON(w, names)
+	}
+}
+
+// apiRecord is the admin API's JSON shape for a single resource record.
+type apiRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	TTL   uint32 `json:"ttl"`
+	Rdata string `json:"rdata"`
+}
+
+func toAPIRecords(rrs []dns.RR) []apiRecord {
+	out := make([]apiRecord, 0, len(rrs))
+	for _, rr := range rrs {
+		h := rr.Header()
+		out = append(out, apiRecord{
+			Name:  h.Name,
+			Type:  dns.Type(h.Rrtype).String(),
+			TTL:   h.Ttl,
+			Rdata: strings.TrimPrefix(rr.String(), h.String()),
+		})
+	}
+	return out
+}
+
+func listRecords(zones map[string]*zone.Zone) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		z, ok := lookupZone(zones, r)
+		if !ok {
+			http.Error(w, "zone not found", http.StatusNotFound)
+			return
+		}
+
+		rrs, err := z.Records()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, toAPIRecords(rrs))
+	}
+}
+
+func createRecord(zones map[string]*zone.Zone) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		z, ok := lookupZone(zones, r)
+		if !ok {
+			http.Error(w, "zone not found", http.StatusNotFound)
+			return
+		}
+
+		var in apiRecord
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, "malformed request body", http.StatusBadRequest)
+			return
+		}
+
+		// in.Rdata is fed into dns.NewRR as zonefile text below; a newline
+		// would let a client smuggle in its own zonefile directives (e.g.
+		// $INCLUDE), so reject any field that could break it onto a line
+		// of its own.
+		if strings.ContainsAny(in.Name, "\r\n") || strings.ContainsAny(in.Type, "\r\n") || strings.ContainsAny(in.Rdata, "\r\n") {
+			http.Error(w, "record fields must not contain newlines", http.StatusBadRequest)
+			return
+		}
+		if _, ok := dns.StringToType[strings.ToUpper(in.Type)]; !ok {
+			http.Error(w, "unknown record type", http.StatusBadRequest)
+			return
+		}
+
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(in.Name), in.TTL, in.Type, in.Rdata))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		msg := new(dns.Msg)
+		msg.SetUpdate(z.Name())
+		msg.Insert([]dns.RR{rr})
+
+		if err := z.ApplyUpdate(msg.Question[0], msg.Ns); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func deleteRecord(zones map[string]*zone.Zone) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		z, ok := lookupZone(zones, r)
+		if !ok {
+			http.Error(w, "zone not found", http.StatusNotFound)
+			return
+		}
+
+		rrtype, ok := dns.StringToType[strings.ToUpper(r.PathValue("type"))]
+		if !ok {
+			http.Error(w, "unknown record type", http.StatusBadRequest)
+			return
+		}
+		newFn, ok := dns.TypeToRR[rrtype]
+		if !ok {
+			http.Error(w, "unknown record type", http.StatusBadRequest)
+			return
+		}
+		rr := newFn()
+		rr.Header().Name = dns.Fqdn(r.PathValue("name"))
+		rr.Header().Rrtype = rrtype
+
+		msg := new(dns.Msg)
+		msg.SetUpdate(z.Name())
+		msg.RemoveRRset([]dns.RR{rr})
+
+		if err := z.ApplyUpdate(msg.Question[0], msg.Ns); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func exportZone(zones map[string]*zone.Zone) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		z, ok := lookupZone(zones, r)
+		if !ok {
+			http.Error(w, "zone not found", http.StatusNotFound)
+			return
+		}
+
+		rrs, err := z.Export()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			writeJSON(w, toAPIRecords(rrs))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, rr := range rrs {
+			fmt.Fprintln(w, rr.String())
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v) // nolint
+}
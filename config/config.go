@@ -10,13 +10,75 @@ type Config struct {
 	Zone       []ConfZone        `json:"zones"`
 	TsigSecret map[string]string `json:"tsig-secret"`
 	LocalAddr  string            `json:"local-addr"`
+	TLS        *TLS              `json:"tls"`
+	Admin      *Admin            `json:"admin"`
 }
 
 type ConfZone struct {
-	DBFile    string `json:"db-file"`
-	ZoneName  string `json:"zone-name"`
-	NsName    string `json:"ns-name"`
-	AllowCIDR string `json:"allow-cidr"`
+	DBFile    string  `json:"db-file"`
+	ZoneName  string  `json:"zone-name"`
+	NsName    string  `json:"ns-name"`
+	AllowCIDR string  `json:"allow-cidr"`
+	DNSSEC    *DNSSEC `json:"dnssec"`
+
+	// NotifyTargets are secondary nameservers (host:port) sent a NOTIFY
+	// (RFC 1996) whenever this zone's records change.
+	NotifyTargets []string `json:"notify-targets"`
+
+	// AnswerOrder controls how multi-value RRsets are ordered in answers:
+	// "off" (default) leaves storage order as-is, "shuffle" randomizes it
+	// per query, and "round-robin" rotates it by a per-key counter. SRV
+	// RRsets always follow RFC 2782 priority/weight selection instead,
+	// whenever AnswerOrder isn't "off".
+	AnswerOrder string `json:"answer-order"`
+}
+
+// DNSSEC points at an on-disk KSK/ZSK keypair (in the key/private file
+// layout produced by `dnssec-keygen`) used to online-sign this zone's
+// answers.
+type DNSSEC struct {
+	KSKKeyFile     string `json:"ksk-key-file"`
+	KSKPrivateFile string `json:"ksk-private-file"`
+	ZSKKeyFile     string `json:"zsk-key-file"`
+	ZSKPrivateFile string `json:"zsk-private-file"`
+}
+
+// TLS configures the optional DNS-over-TLS (RFC 7858) and DNS-over-HTTPS
+// (RFC 8484) listeners. Both share the same certificate/key pair.
+type TLS struct {
+	CertFile string `json:"cert-file"`
+	KeyFile  string `json:"key-file"`
+
+	// DoTAddr is the listen address for DNS-over-TLS, e.g. ":853".
+	DoTAddr string `json:"dot-addr"`
+
+	// DoHAddr is the listen address for DNS-over-HTTPS, e.g. ":443".
+	DoHAddr string `json:"doh-addr"`
+	// DoHPath is the HTTP path the DoH endpoint is served on, e.g. "/dns-query".
+	DoHPath string `json:"doh-path"`
+}
+
+// Admin configures the optional HTTP admin API (see package admin), which
+// exposes record CRUD and zone export over REST instead of a TSIG'd
+// nsupdate client.
+type Admin struct {
+	// Addr is the listen address for the admin API, e.g. ":8444".
+	Addr string `json:"addr"`
+
+	// CertFile/KeyFile, if both set, serve the admin API over TLS.
+	CertFile string `json:"cert-file"`
+	KeyFile  string `json:"key-file"`
+
+	// ClientCAFile, if set, requires and verifies a client certificate
+	// signed by this CA (mTLS) for every request. It takes effect only when
+	// CertFile and KeyFile are set; over plain HTTP no client certificate
+	// is checked.
+	ClientCAFile string `json:"client-ca-file"`
+
+	// BearerTokens authorizes requests sending "Authorization: Bearer
+	// <token>" matching one of these values. When ClientCAFile is also set,
+	// a request needs both the client certificate and a matching token.
+	BearerTokens []string `json:"bearer-tokens"`
 }
 
 func Load(filename string) (*Config, error) {
@@ -0,0 +1,154 @@
+// Package doh bridges DNS-over-HTTPS (RFC 8484) requests onto the standard
+// miekg/dns ServeMux, so the same zone.Zone.HandleRequest dispatcher that
+// answers plain UDP/TCP queries can answer DoH ones too.
+package doh
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/miekg/dns"
+)
+
+const mimeType = "application/dns-message"
+
+// Handler returns an http.Handler serving DoH requests, verifying and
+// re-signing TSIG using secret the same way the UDP/TCP servers do.
+func Handler(tsigSecret map[string]string) http.Handler {
+	return &handler{tsigSecret: tsigSecret}
+}
+
+type handler struct {
+	tsigSecret map[string]string
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf []byte
+	var err error
+
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query().Get("dns")
+		if q == "" {
+			http.Error(w, "missing dns parameter", http.StatusBadRequest)
+			return
+		}
+		buf, err = base64.RawURLEncoding.DecodeString(q)
+	case http.MethodPost:
+		if r.Header.Get("Content-Type") != mimeType {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		buf, err = io.ReadAll(r.Body)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+
+	req := new(dns.Msg)
+	if err := req.Unpack(buf); err != nil {
+		http.Error(w, "malformed dns message", http.StatusBadRequest)
+		return
+	}
+
+	rw := newResponseWriter(r.RemoteAddr, h.tsigSecret)
+	rw.verifyTsig(buf, req)
+
+	dns.DefaultServeMux.ServeDNS(rw, req)
+
+	if rw.msg == nil {
+		http.Error(w, "no response", http.StatusInternalServerError)
+		return
+	}
+
+	out, err := rw.pack()
+	if err != nil {
+		http.Error(w, "failed to pack response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mimeType)
+	w.Write(out) // nolint
+}
+
+// responseWriter adapts a single HTTP request/response pair onto
+// dns.ResponseWriter, packing and unpacking *dns.Msg in memory instead of
+// reading/writing a net.Conn.
+type responseWriter struct {
+	raddr net.Addr
+	laddr net.Addr
+
+	tsigSecret map[string]string
+	tsigStatus error
+	tsigReqMAC string
+
+	msg *dns.Msg
+}
+
+func newResponseWriter(remoteAddr string, tsigSecret map[string]string) *responseWriter {
+	raddr, _ := net.ResolveTCPAddr("tcp", remoteAddr)
+	return &responseWriter{
+		raddr:      raddr,
+		laddr:      &net.TCPAddr{},
+		tsigSecret: tsigSecret,
+	}
+}
+
+// verifyTsig mirrors dns.Server's verification of an incoming TSIG, so
+// zone.Zone.HandleRequest sees the same w.TsigStatus() contract regardless
+// of transport.
+func (w *responseWriter) verifyTsig(buf []byte, req *dns.Msg) {
+	t := req.IsTsig()
+	if t == nil {
+		return
+	}
+
+	secret, ok := w.tsigSecret[t.Hdr.Name]
+	if !ok {
+		w.tsigStatus = dns.ErrSecret
+		return
+	}
+
+	w.tsigStatus = dns.TsigVerify(buf, secret, "", false)
+	w.tsigReqMAC = t.MAC
+}
+
+// pack packs the response, (re-)signing TSIG the way dns.Server does.
+func (w *responseWriter) pack() ([]byte, error) {
+	if t := w.msg.IsTsig(); t != nil {
+		secret, ok := w.tsigSecret[t.Hdr.Name]
+		if ok {
+			data, _, err := dns.TsigGenerate(w.msg, secret, w.tsigReqMAC, false)
+			return data, err
+		}
+	}
+	return w.msg.Pack()
+}
+
+func (w *responseWriter) LocalAddr() net.Addr  { return w.laddr }
+func (w *responseWriter) RemoteAddr() net.Addr { return w.raddr }
+
+func (w *responseWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	m := new(dns.Msg)
+	if err := m.Unpack(b); err != nil {
+		return 0, err
+	}
+	w.msg = m
+	return len(b), nil
+}
+
+func (w *responseWriter) Close() error        { return nil }
+func (w *responseWriter) TsigStatus() error   { return w.tsigStatus }
+func (w *responseWriter) TsigTimersOnly(bool) {}
+func (w *responseWriter) Hijack()             {}
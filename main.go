@@ -7,9 +7,12 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -18,7 +21,9 @@ import (
 	"time"
 
 	"github.com/miekg/dns"
+	"github.com/yseto/dynamic-dns-go/admin"
 	"github.com/yseto/dynamic-dns-go/config"
+	"github.com/yseto/dynamic-dns-go/doh"
 	"github.com/yseto/dynamic-dns-go/zone"
 )
 
@@ -43,8 +48,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	zones := make(map[string]*zone.Zone, len(conf.Zone))
 	for _, i := range conf.Zone {
-		z, err := zone.New(i.ZoneName, i.NsName, i.DBFile, conf.LocalAddr, i.AllowCIDR)
+		z, err := zone.New(i, conf.LocalAddr)
 		if err != nil {
 			slog.Error(err.Error())
 			os.Exit(1)
@@ -56,11 +62,12 @@ func main() {
 
 		// Attach request handler func
 		dns.HandleFunc(i.ZoneName, z.HandleRequest)
+		zones[dns.Fqdn(i.ZoneName)] = z
 	}
 
 	addr := net.JoinHostPort("", strconv.Itoa(*port))
 
-	udpServer := createServer(addr, "udp", conf.TsigSecret)
+	udpServer := createServer(addr, "udp", conf.TsigSecret, nil)
 	go func() {
 		if err := udpServer.ListenAndServe(); err != nil {
 			slog.Error(err.Error())
@@ -68,7 +75,7 @@ func main() {
 		}
 	}()
 
-	tcpServer := createServer(addr, "tcp", conf.TsigSecret)
+	tcpServer := createServer(addr, "tcp", conf.TsigSecret, nil)
 	go func() {
 		if err := tcpServer.ListenAndServe(); err != nil {
 			slog.Error(err.Error())
@@ -76,6 +83,83 @@ func main() {
 		}
 	}()
 
+	var dotServer *dns.Server
+	var dohServer *http.Server
+	if conf.TLS != nil {
+		cert, err := tls.LoadX509KeyPair(conf.TLS.CertFile, conf.TLS.KeyFile)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+		dotServer = createServer(conf.TLS.DoTAddr, "tcp-tls", conf.TsigSecret, tlsConfig)
+		go func() {
+			if err := dotServer.ListenAndServe(); err != nil {
+				slog.Error(err.Error())
+				cancel()
+			}
+		}()
+
+		// DoH is independently optional: an operator may set up TLS for
+		// DoT only, leaving doh-path/doh-addr unset.
+		if conf.TLS.DoHPath != "" && conf.TLS.DoHAddr != "" {
+			mux := http.NewServeMux()
+			mux.Handle(conf.TLS.DoHPath, doh.Handler(conf.TsigSecret))
+			dohServer = &http.Server{
+				Addr:      conf.TLS.DoHAddr,
+				Handler:   mux,
+				TLSConfig: tlsConfig,
+			}
+			go func() {
+				slog.Info("Server starting", "address", conf.TLS.DoHAddr, "proto", "doh")
+				if err := dohServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+					slog.Error(err.Error())
+					cancel()
+				}
+			}()
+		}
+	}
+
+	var adminServer *http.Server
+	if conf.Admin != nil {
+		adminServer = &http.Server{
+			Addr:    conf.Admin.Addr,
+			Handler: admin.Handler(zones, conf.Admin.BearerTokens),
+		}
+
+		if conf.Admin.ClientCAFile != "" {
+			ca, err := os.ReadFile(conf.Admin.ClientCAFile)
+			if err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				slog.Error("failed to parse client-ca-file")
+				os.Exit(1)
+			}
+			adminServer.TLSConfig = &tls.Config{
+				ClientCAs:  pool,
+				ClientAuth: tls.RequireAndVerifyClientCert,
+			}
+		}
+
+		go func() {
+			slog.Info("Server starting", "address", conf.Admin.Addr, "proto", "admin")
+			var err error
+			if conf.Admin.CertFile != "" && conf.Admin.KeyFile != "" {
+				err = adminServer.ListenAndServeTLS(conf.Admin.CertFile, conf.Admin.KeyFile)
+			} else {
+				err = adminServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				slog.Error(err.Error())
+				cancel()
+			}
+		}()
+	}
+
 	<-ctx.Done()
 	slog.Info("Server stopping")
 
@@ -101,11 +185,44 @@ func main() {
 		}
 	}(cT)
 
+	if dotServer != nil {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			if err := dotServer.ShutdownContext(ctx); err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+		}(cT)
+	}
+
+	if dohServer != nil {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			if err := dohServer.Shutdown(ctx); err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+		}(cT)
+	}
+
+	if adminServer != nil {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			if err := adminServer.Shutdown(ctx); err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+		}(cT)
+	}
+
 	wg.Wait()
 	slog.Info("Server gracefully stopped")
 }
 
-func createServer(addr, proto string, tsigSecret map[string]string) *dns.Server {
+func createServer(addr, proto string, tsigSecret map[string]string, tlsConfig *tls.Config) *dns.Server {
 	server := &dns.Server{
 		Addr: addr,
 		Net:  proto,
@@ -127,5 +244,8 @@ func createServer(addr, proto string, tsigSecret map[string]string) *dns.Server
 	if len(tsigSecret) > 0 {
 		server.TsigSecret = tsigSecret
 	}
+	if tlsConfig != nil {
+		server.TLSConfig = tlsConfig
+	}
 	return server
 }
@@ -0,0 +1,26 @@
+package record
+
+import "iter"
+
+// Backend is the storage primitive pad needs to apply RFC2136 updates.
+// Zone owns the concrete store (file-backed, SQLite, in-memory, ...) and
+// passes it to NewPad; pad never deals with a store's persistence details.
+type Backend interface {
+	// Load opens/prepares the underlying store for use.
+	Load() error
+	// Get returns the stored RR strings for key, or nil if key is absent.
+	Get(key string) []string
+	// Put replaces the RR strings stored under key, creating key if needed.
+	Put(key string, values []string) error
+	// Delete removes key and its RR strings entirely.
+	Delete(key string) error
+	// DeletePrefix removes every key sharing prefix.
+	DeletePrefix(prefix string) error
+	// HasPrefix reports whether any key shares prefix.
+	HasPrefix(prefix string) (bool, error)
+	// Iterate yields every stored key and its RR strings.
+	Iterate() iter.Seq2[string, []string]
+	// Flush commits pending writes made since the last Flush. Backends that
+	// write through immediately (SQLite, in-memory) may treat this as a no-op.
+	Flush() error
+}
@@ -15,32 +15,22 @@ var (
 )
 
 type pad struct {
-	records map[string][]string
+	backend Backend
 }
 
-func NewPad(r map[string][]string) *pad {
+func NewPad(b Backend) *pad {
 	return &pad{
-		records: r,
+		backend: b,
 	}
 }
 
-func (s *pad) Records() map[string][]string {
-	return s.records
-}
-
 func (s *pad) deleteRecords(domain string) error {
 	prefix, err := GetKeyDomain(domain)
 	if err != nil {
 		return err
 	}
 
-	for key := range s.records {
-		if strings.HasPrefix(key, prefix) {
-			delete(s.records, key)
-		}
-	}
-
-	return nil
+	return s.backend.DeletePrefix(prefix)
 }
 
 func (s *pad) deleteRecord(domain string, rtype uint16) error {
@@ -53,8 +43,7 @@ func (s *pad) deleteRecord(domain string, rtype uint16) error {
 		return err
 	}
 
-	delete(s.records, key)
-	return nil
+	return s.backend.Delete(key)
 }
 
 func (s *pad) hasRecords(domain string) (bool, error) {
@@ -63,13 +52,7 @@ func (s *pad) hasRecords(domain string) (bool, error) {
 		return false, err
 	}
 
-	for key := range s.records {
-		if strings.HasPrefix(key, prefix) {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return s.backend.HasPrefix(prefix)
 }
 
 func (s *pad) storeRecord(rr dns.RR) error {
@@ -89,7 +72,7 @@ func (s *pad) storeRecord(rr dns.RR) error {
 	if err != nil {
 		return nil
 	}
-	if _, ok := s.records[key]; ok {
+	if len(s.backend.Get(key)) > 0 {
 		return errCNAMERecordIsExist
 	}
 
@@ -99,14 +82,15 @@ func (s *pad) storeRecord(rr dns.RR) error {
 		return err
 	}
 
+	values := s.backend.Get(key)
+
 	// gaurd dup.
 	// Any duplicate RRs will be silently ignored by the primary master.
-	if slices.Contains(s.records[key], rr.String()) {
+	if slices.Contains(values, rr.String()) {
 		return nil
 	}
 
-	s.records[key] = append(s.records[key], rr.String())
-	return nil
+	return s.backend.Put(key, append(values, rr.String()))
 }
 
 func (s *pad) omitRecord(rr dns.RR) error {
@@ -115,8 +99,8 @@ func (s *pad) omitRecord(rr dns.RR) error {
 		return nil
 	}
 
-	values, ok := s.records[key]
-	if !ok {
+	values := s.backend.Get(key)
+	if values == nil {
 		return nil
 	}
 
@@ -139,9 +123,7 @@ func (s *pad) omitRecord(rr dns.RR) error {
 		newRWRecords = append(newRWRecords, v)
 	}
 
-	s.records[key] = newRWRecords
-
-	return nil
+	return s.backend.Put(key, newRWRecords)
 }
 
 func (s *pad) UpdateRecord(r dns.RR, q *dns.Question) error {
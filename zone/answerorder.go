@@ -0,0 +1,104 @@
+package zone
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/miekg/dns"
+)
+
+// reorderAnswers applies the zone's configured AnswerOrder policy to rrs, the
+// records matched for one query (for an ANY query, every RRset at the name).
+// key identifies the queried name and type for the round-robin counter. SRV RRsets are special-cased per RFC 2782 priority/
+// weight selection instead of a plain shuffle/rotation, whenever
+// z.answerOrder isn't "off", since SRV's own fields already dictate order.
+func (z *Zone) reorderAnswers(key string, qQtype uint16, rrs []dns.RR) []dns.RR {
+	if len(rrs) < 2 || z.answerOrder == "" || z.answerOrder == "off" {
+		return rrs
+	}
+
+	if qQtype == dns.TypeSRV {
+		return weightedSRV(rrs)
+	}
+
+	switch z.answerOrder {
+	case "shuffle":
+		shuffled := append([]dns.RR(nil), rrs...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		return shuffled
+	case "round-robin":
+		z.mu.Lock()
+		offset := int(z.rrCounters[key] % uint64(len(rrs)))
+		z.rrCounters[key]++
+		z.mu.Unlock()
+
+		rotated := make([]dns.RR, len(rrs))
+		for i := range rrs {
+			rotated[i] = rrs[(offset+i)%len(rrs)]
+		}
+		return rotated
+	default:
+		return rrs
+	}
+}
+
+// weightedSRV orders SRV RRs per RFC 2782: grouped by priority (ascending),
+// and within each priority group drawn by weighted random selection without
+// replacement, so higher-weight targets are more likely to come first.
+func weightedSRV(rrs []dns.RR) []dns.RR {
+	groups := map[uint16][]*dns.SRV{}
+	var priorities []uint16
+	for _, rr := range rrs {
+		srv, ok := rr.(*dns.SRV)
+		if !ok {
+			// not all RRs at this owner/type are SRV; leave untouched
+			return rrs
+		}
+		if _, seen := groups[srv.Priority]; !seen {
+			priorities = append(priorities, srv.Priority)
+		}
+		groups[srv.Priority] = append(groups[srv.Priority], srv)
+	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] < priorities[j] })
+
+	out := make([]dns.RR, 0, len(rrs))
+	for _, p := range priorities {
+		out = append(out, weightedOrder(groups[p])...)
+	}
+	return out
+}
+
+// weightedOrder repeatedly draws a uniform int in [0, sum of remaining
+// weights] and emits the target whose running weight total first reaches
+// it, until the group is exhausted (RFC 2782's selection algorithm).
+func weightedOrder(group []*dns.SRV) []dns.RR {
+	remaining := append([]*dns.SRV(nil), group...)
+	out := make([]dns.RR, 0, len(remaining))
+
+	for len(remaining) > 0 {
+		total := 0
+		for _, s := range remaining {
+			total += int(s.Weight)
+		}
+
+		pick := 0
+		if total > 0 {
+			draw := rand.Intn(total + 1)
+			sum := 0
+			for i, s := range remaining {
+				sum += int(s.Weight)
+				if draw <= sum {
+					pick = i
+					break
+				}
+			}
+		} else {
+			pick = rand.Intn(len(remaining))
+		}
+
+		out = append(out, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+
+	return out
+}
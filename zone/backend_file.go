@@ -0,0 +1,144 @@
+package zone
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"iter"
+	"os"
+	"strings"
+	"sync"
+)
+
+// fileBackend is the original JSON-lines file store: the whole zone is kept
+// in memory and flushed back to disk in full on Flush.
+type fileBackend struct {
+	mu       sync.Mutex
+	filename string
+	records  map[string][]string
+}
+
+// Dic is the on-disk JSON-lines record shape: one line per domain.
+type Dic struct {
+	Domain  string   `json:"domain"`
+	Records []string `json:"records"`
+}
+
+func newFileBackend(filename string) *fileBackend {
+	return &fileBackend{
+		filename: filename,
+		records:  map[string][]string{},
+	}
+}
+
+func (b *fileBackend) Load() error {
+	f, err := os.Open(b.filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dec := json.NewDecoder(f)
+	for {
+		var v Dic
+		if err := dec.Decode(&v); err == io.EOF {
+			break // done decoding file
+		} else if err != nil {
+			return err
+		}
+		b.records[v.Domain] = v.Records
+	}
+	return nil
+}
+
+func (b *fileBackend) Get(key string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.records[key]
+}
+
+func (b *fileBackend) Put(key string, values []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[key] = values
+	return nil
+}
+
+func (b *fileBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.records, key)
+	return nil
+}
+
+func (b *fileBackend) DeletePrefix(prefix string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.records {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.records, key)
+		}
+	}
+	return nil
+}
+
+func (b *fileBackend) HasPrefix(prefix string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.records {
+		if strings.HasPrefix(key, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (b *fileBackend) Iterate() iter.Seq2[string, []string] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// snapshot so the caller can range over it without holding the lock
+	snapshot := make(map[string][]string, len(b.records))
+	for k, v := range b.records {
+		snapshot[k] = v
+	}
+
+	return func(yield func(string, []string) bool) {
+		for k, v := range snapshot {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Flush rewrites the whole file with the current in-memory state. This is
+// the one place the file store pays for not being a real database: every
+// commit rewrites everything, so callers should batch a whole UPDATE
+// message's worth of pad.UpdateRecord calls into a single Flush.
+func (b *fileBackend) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := os.Create(b.filename)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for k, v := range b.records {
+		bs, err := json.Marshal(Dic{Domain: k, Records: v})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(bs, '\n')); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,83 @@
+package zone
+
+import (
+	"iter"
+	"strings"
+	"sync"
+)
+
+// memBackend is a pure in-memory store with no persistence, selected via
+// the `mem://` db-file scheme. Useful for tests and ephemeral zones.
+type memBackend struct {
+	mu      sync.Mutex
+	records map[string][]string
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{
+		records: map[string][]string{},
+	}
+}
+
+func (b *memBackend) Load() error { return nil }
+
+func (b *memBackend) Get(key string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.records[key]
+}
+
+func (b *memBackend) Put(key string, values []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[key] = values
+	return nil
+}
+
+func (b *memBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.records, key)
+	return nil
+}
+
+func (b *memBackend) DeletePrefix(prefix string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.records {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.records, key)
+		}
+	}
+	return nil
+}
+
+func (b *memBackend) HasPrefix(prefix string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.records {
+		if strings.HasPrefix(key, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (b *memBackend) Iterate() iter.Seq2[string, []string] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	snapshot := make(map[string][]string, len(b.records))
+	for k, v := range b.records {
+		snapshot[k] = v
+	}
+
+	return func(yield func(string, []string) bool) {
+		for k, v := range snapshot {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func (b *memBackend) Flush() error { return nil }
@@ -0,0 +1,125 @@
+package zone
+
+import (
+	"database/sql"
+	"iter"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteBackend is selected via the `sqlite://` db-file scheme, e.g.
+// `sqlite:///var/lib/ddns/example.com.db`. Unlike fileBackend it writes
+// through immediately: every Put/Delete/DeletePrefix runs its own
+// transaction, so large zones don't pay for rewriting the whole zone on
+// every UPDATE.
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+func newSqliteBackend(dsn string) (*sqliteBackend, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteBackend{db: db}, nil
+}
+
+func (b *sqliteBackend) Load() error {
+	_, err := b.db.Exec(`CREATE TABLE IF NOT EXISTS records (
+		key   TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (key, value)
+	)`)
+	return err
+}
+
+func (b *sqliteBackend) Get(key string) []string {
+	rows, err := b.db.Query(`SELECT value FROM records WHERE key = ?`, key)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func (b *sqliteBackend) Put(key string, values []string) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint
+
+	if _, err := tx.Exec(`DELETE FROM records WHERE key = ?`, key); err != nil {
+		return err
+	}
+	for _, v := range values {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO records (key, value) VALUES (?, ?)`, key, v); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (b *sqliteBackend) Delete(key string) error {
+	_, err := b.db.Exec(`DELETE FROM records WHERE key = ?`, key)
+	return err
+}
+
+// DeletePrefix and HasPrefix compare with substr instead of GLOB so that a
+// prefix containing GLOB metacharacters (e.g. the "*" in a wildcard record's
+// key) is matched literally, the same as the other backends' strings.HasPrefix.
+func (b *sqliteBackend) DeletePrefix(prefix string) error {
+	_, err := b.db.Exec(`DELETE FROM records WHERE substr(key, 1, length(?)) = ?`, prefix, prefix)
+	return err
+}
+
+func (b *sqliteBackend) HasPrefix(prefix string) (bool, error) {
+	var exists bool
+	err := b.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM records WHERE substr(key, 1, length(?)) = ?)`, prefix, prefix).Scan(&exists)
+	return exists, err
+}
+
+func (b *sqliteBackend) Iterate() iter.Seq2[string, []string] {
+	return func(yield func(string, []string) bool) {
+		rows, err := b.db.Query(`SELECT key, value FROM records ORDER BY key`)
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+
+		var curKey string
+		var curValues []string
+		flush := func() bool {
+			if curKey == "" {
+				return true
+			}
+			return yield(curKey, curValues)
+		}
+
+		for rows.Next() {
+			var key, value string
+			if err := rows.Scan(&key, &value); err != nil {
+				return
+			}
+			if key != curKey {
+				if !flush() {
+					return
+				}
+				curKey, curValues = key, nil
+			}
+			curValues = append(curValues, value)
+		}
+		flush()
+	}
+}
+
+func (b *sqliteBackend) Flush() error { return nil }
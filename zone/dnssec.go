@@ -0,0 +1,188 @@
+package zone
+
+import (
+	"crypto"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/yseto/dynamic-dns-go/config"
+)
+
+// signatureValidity is how long an online-generated RRSIG stays valid.
+const signatureValidity = 7 * 24 * time.Hour
+
+// dnssecSigner holds the KSK/ZSK keypair used to online-sign answers for a
+// single zone.
+type dnssecSigner struct {
+	ksk     *dns.DNSKEY
+	kskPriv crypto.Signer
+	zsk     *dns.DNSKEY
+	zskPriv crypto.Signer
+}
+
+func newDNSSECSigner(c *config.DNSSEC) (*dnssecSigner, error) {
+	ksk, kskPriv, err := loadKeyPair(c.KSKKeyFile, c.KSKPrivateFile)
+	if err != nil {
+		return nil, err
+	}
+	zsk, zskPriv, err := loadKeyPair(c.ZSKKeyFile, c.ZSKPrivateFile)
+	if err != nil {
+		return nil, err
+	}
+	return &dnssecSigner{ksk: ksk, kskPriv: kskPriv, zsk: zsk, zskPriv: zskPriv}, nil
+}
+
+// loadKeyPair reads a dnssec-keygen style public/private key file pair: the
+// key file holds the zone-file DNSKEY record, the private file holds the
+// matching BIND private-key format.
+func loadKeyPair(keyFile, privFile string) (*dns.DNSKEY, crypto.Signer, error) {
+	keyText, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	rr, err := dns.NewRR(string(keyText))
+	if err != nil {
+		return nil, nil, err
+	}
+	dnskey, ok := rr.(*dns.DNSKEY)
+	if !ok {
+		return nil, nil, dns.ErrKey
+	}
+
+	privFh, err := os.Open(privFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer privFh.Close()
+
+	priv, err := dnskey.ReadPrivateKey(privFh, privFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, nil, dns.ErrKey
+	}
+
+	return dnskey, signer, nil
+}
+
+// dnskeyRRset returns the apex DNSKEY RRset (KSK + ZSK), for answering
+// QTYPE=DNSKEY queries.
+func (s *dnssecSigner) dnskeyRRset() []dns.RR {
+	return []dns.RR{s.ksk, s.zsk}
+}
+
+// sign produces an RRSIG over rrset, using the KSK to sign the apex DNSKEY
+// RRset and the ZSK for everything else.
+func (s *dnssecSigner) sign(rrset []dns.RR, signerName string) (*dns.RRSIG, error) {
+	if len(rrset) == 0 {
+		return nil, nil
+	}
+
+	key, priv := s.zsk, s.zskPriv
+	if rrset[0].Header().Rrtype == dns.TypeDNSKEY {
+		key, priv = s.ksk, s.kskPriv
+	}
+
+	now := time.Now()
+	rrsig := &dns.RRSIG{
+		Inception:  uint32(now.Add(-1 * time.Hour).Unix()),
+		Expiration: uint32(now.Add(signatureValidity).Unix()),
+		KeyTag:     key.KeyTag(),
+		SignerName: signerName,
+		Algorithm:  key.Algorithm,
+	}
+	if err := rrsig.Sign(priv, rrset); err != nil {
+		return nil, err
+	}
+	return rrsig, nil
+}
+
+// signRRsets groups rrs by (name, type) and appends one RRSIG per group.
+func (s *dnssecSigner) signRRsets(rrs []dns.RR, signerName string) []dns.RR {
+	type rrsetKey struct {
+		name  string
+		rtype uint16
+	}
+	order := make([]rrsetKey, 0, len(rrs))
+	sets := make(map[rrsetKey][]dns.RR, len(rrs))
+	for _, rr := range rrs {
+		k := rrsetKey{strings.ToLower(rr.Header().Name), rr.Header().Rrtype}
+		if _, ok := sets[k]; !ok {
+			order = append(order, k)
+		}
+		sets[k] = append(sets[k], rr)
+	}
+
+	out := make([]dns.RR, 0, len(rrs))
+	for _, k := range order {
+		out = append(out, sets[k]...)
+		rrsig, err := s.sign(sets[k], signerName)
+		if err != nil {
+			continue
+		}
+		out = append(out, rrsig)
+	}
+	return out
+}
+
+// canonicalLess reports whether a sorts before b under RFC 4034 §6.1 DNS
+// name canonical ordering: names are compared label by label from the root
+// down, not as flat strings, so e.g. "ab.example.com." sorts before
+// "a.b.example.com." even though the reverse holds under a plain string
+// comparison.
+func canonicalLess(a, b string) bool {
+	al := dns.SplitDomainName(a)
+	bl := dns.SplitDomainName(b)
+	slices.Reverse(al)
+	slices.Reverse(bl)
+
+	for i := 0; i < len(al) && i < len(bl); i++ {
+		if al[i] != bl[i] {
+			return al[i] < bl[i]
+		}
+	}
+	return len(al) < len(bl)
+}
+
+// nsec synthesizes a covering NSEC record for qName out of the zone's
+// current keyspace, per RFC 4034 §4. owners is the sorted set of every
+// owner name that currently has records (closest-encloser candidates).
+func nsec(qName, zoneApex string, owners []string, typesAtOwner func(owner string) []uint16) dns.RR {
+	sorted := append([]string(nil), owners...)
+	sort.Slice(sorted, func(i, j int) bool { return canonicalLess(sorted[i], sorted[j]) })
+
+	qName = strings.ToLower(qName)
+
+	owner := zoneApex
+	next := zoneApex
+	for i, o := range sorted {
+		if canonicalLess(qName, o) {
+			next = o
+			if i > 0 {
+				owner = sorted[i-1]
+			}
+			break
+		}
+		owner = o
+	}
+
+	bitmap := append([]uint16{dns.TypeNSEC, dns.TypeRRSIG}, typesAtOwner(owner)...)
+	sort.Slice(bitmap, func(i, j int) bool { return bitmap[i] < bitmap[j] })
+
+	return &dns.NSEC{
+		Hdr: dns.RR_Header{
+			Name:   owner,
+			Rrtype: dns.TypeNSEC,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		NextDomain: next,
+		TypeBitMap: bitmap,
+	}
+}
@@ -0,0 +1,40 @@
+package zone
+
+import "testing"
+
+func TestCanonicalLess(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		// RFC 4034 §6.1: canonical order compares label-wise from the
+		// root down, so "ab" (one label) sorts before "a.b" (two
+		// labels) even though plain string comparison says the reverse.
+		{"ab.example.com.", "a.b.example.com.", true},
+		{"a.b.example.com.", "ab.example.com.", false},
+		{"a.example.com.", "b.example.com.", true},
+		{"example.com.", "a.example.com.", true},
+		{"example.com.", "example.com.", false},
+	}
+
+	for _, c := range cases {
+		if got := canonicalLess(c.a, c.b); got != c.want {
+			t.Errorf("canonicalLess(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestNSECCanonicalOrdering(t *testing.T) {
+	owners := []string{"ab.example.com.", "a.b.example.com.", "example.com."}
+	typesAt := func(string) []uint16 { return nil }
+
+	// Under canonical order the owners sort as:
+	//   example.com. < ab.example.com. < a.b.example.com.
+	// so a query between "ab.example.com." and "a.b.example.com." should
+	// be covered by an NSEC owned at "ab.example.com.".
+	rr := nsec("ac.example.com.", "example.com.", owners, typesAt)
+	got := rr.Header().Name
+	if got != "ab.example.com." {
+		t.Fatalf("expected NSEC owner ab.example.com., got %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package zone
+
+import (
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/yseto/dynamic-dns-go/record"
+)
+
+// maxJournalEntries bounds how far back in serial history IXFR can serve a
+// delta from; older entries are dropped and those clients fall back to AXFR.
+const maxJournalEntries = 100
+
+// journalEntry is one UPDATE transaction's worth of RFC 1995 delta: the
+// RRs removed and added going from oldSerial to newSerial.
+type journalEntry struct {
+	oldSerial int64
+	newSerial int64
+	removed   []string
+	added     []string
+}
+
+// journalRecorder wraps a Zone's backend during a single UPDATE transaction,
+// remembering each touched key's value before the first write so the
+// transaction's net add/remove delta can be recovered afterwards for the
+// IXFR journal.
+type journalRecorder struct {
+	backend record.Backend
+	before  map[string][]string
+	touched []string
+}
+
+func newJournalRecorder(backend record.Backend) *journalRecorder {
+	return &journalRecorder{
+		backend: backend,
+		before:  map[string][]string{},
+	}
+}
+
+func (j *journalRecorder) remember(key string) {
+	if _, ok := j.before[key]; ok {
+		return
+	}
+	j.before[key] = append([]string(nil), j.backend.Get(key)...)
+	j.touched = append(j.touched, key)
+}
+
+func (j *journalRecorder) Load() error             { return j.backend.Load() }
+func (j *journalRecorder) Get(key string) []string { return j.backend.Get(key) }
+
+func (j *journalRecorder) Put(key string, values []string) error {
+	j.remember(key)
+	return j.backend.Put(key, values)
+}
+
+func (j *journalRecorder) Delete(key string) error {
+	j.remember(key)
+	return j.backend.Delete(key)
+}
+
+func (j *journalRecorder) DeletePrefix(prefix string) error {
+	for key := range j.backend.Iterate() {
+		if strings.HasPrefix(key, prefix) {
+			j.remember(key)
+		}
+	}
+	return j.backend.DeletePrefix(prefix)
+}
+
+func (j *journalRecorder) HasPrefix(prefix string) (bool, error) {
+	return j.backend.HasPrefix(prefix)
+}
+
+func (j *journalRecorder) Iterate() iter.Seq2[string, []string] {
+	return j.backend.Iterate()
+}
+
+func (j *journalRecorder) Flush() error { return j.backend.Flush() }
+
+// diff returns the net RRs added and removed across every key touched
+// during the transaction.
+func (j *journalRecorder) diff() (added, removed []string) {
+	for _, key := range j.touched {
+		before := j.before[key]
+		after := j.backend.Get(key)
+
+		for _, v := range after {
+			if !slices.Contains(before, v) {
+				added = append(added, v)
+			}
+		}
+		for _, v := range before {
+			if !slices.Contains(after, v) {
+				removed = append(removed, v)
+			}
+		}
+	}
+	return added, removed
+}
+
+// appendJournal records one transaction's delta, trimming the oldest
+// entries once maxJournalEntries is exceeded.
+func (z *Zone) appendJournal(oldSerial, newSerial int64, added, removed []string) {
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	z.journal = append(z.journal, journalEntry{
+		oldSerial: oldSerial,
+		newSerial: newSerial,
+		added:     added,
+		removed:   removed,
+	})
+
+	if len(z.journal) > maxJournalEntries {
+		z.journal = z.journal[len(z.journal)-maxJournalEntries:]
+	}
+}
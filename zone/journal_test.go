@@ -0,0 +1,58 @@
+package zone
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestNextSerialIsMonotonic(t *testing.T) {
+	now := int64(1000)
+
+	if got := nextSerial(now); got <= now {
+		t.Fatalf("nextSerial(%d) = %d, want > %d", now, got, now)
+	}
+
+	// Simulate two updates landing in the same wall-clock second: the
+	// second call must still move the serial forward.
+	first := nextSerial(now)
+	second := nextSerial(first)
+	if second <= first {
+		t.Fatalf("second nextSerial call did not advance: first=%d second=%d", first, second)
+	}
+}
+
+func TestApplyUpdateAdvancesSerialWithinSameSecond(t *testing.T) {
+	z := newTestZone(t, "example.com.")
+
+	insert := func(rdata string) int64 {
+		rr, err := dns.NewRR(rdata)
+		if err != nil {
+			t.Fatalf("parsing %q: %v", rdata, err)
+		}
+		msg := new(dns.Msg)
+		msg.SetUpdate(z.Name())
+		msg.Insert([]dns.RR{rr})
+		if err := z.ApplyUpdate(msg.Question[0], msg.Ns); err != nil {
+			t.Fatalf("ApplyUpdate(%q): %v", rdata, err)
+		}
+		return z.mtime
+	}
+
+	firstSerial := insert("a.example.com. 3600 IN A 1.1.1.1")
+	secondSerial := insert("b.example.com. 3600 IN A 2.2.2.2")
+
+	if secondSerial <= firstSerial {
+		t.Fatalf("serial did not advance across consecutive updates: %d then %d", firstSerial, secondSerial)
+	}
+
+	// A secondary that already saw firstSerial must be served the delta
+	// that produced secondSerial, not the "already current" shortcut.
+	rrs, ok := z.ixfrRecord(uint32(firstSerial))
+	if !ok {
+		t.Fatal("expected ixfrRecord to find a delta from firstSerial")
+	}
+	if len(rrs) < 2 {
+		t.Fatalf("expected at least bracketing SOA + added record, got %d RRs", len(rrs))
+	}
+}
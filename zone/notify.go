@@ -0,0 +1,91 @@
+package zone
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// notifySecondaries sends an RFC 1996 NOTIFY (QTYPE=SOA) to every configured
+// secondary, best-effort and in the background so a slow/unreachable
+// secondary never blocks the UPDATE response.
+func (z *Zone) notifySecondaries() {
+	if len(z.notifyTargets) == 0 {
+		return
+	}
+
+	m := new(dns.Msg)
+	m.SetNotify(z.zoneName)
+
+	client := &dns.Client{Timeout: 5 * time.Second}
+
+	for _, target := range z.notifyTargets {
+		go func(target string) {
+			if _, _, err := client.Exchange(m, target); err != nil {
+				slog.Warn("notify failed", "target", target, "zone", z.zoneName, "detail", err.Error())
+			}
+		}(target)
+	}
+}
+
+// ixfrClientSerial extracts the SOA serial a client sent in the authority
+// section of an IXFR query, per RFC 1995 §3.
+func ixfrClientSerial(r *dns.Msg) (uint32, bool) {
+	for _, rr := range r.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Serial, true
+		}
+	}
+	return 0, false
+}
+
+// ixfrRecord serves an incremental zone transfer from the journal, or
+// reports false when the journal doesn't go back to clientSerial so the
+// caller should fall back to a full AXFR.
+//
+// z.mtime and z.journal are also written by ApplyUpdate under z.mu, so both
+// are snapshotted under the same lock before use here.
+func (z *Zone) ixfrRecord(clientSerial uint32) ([]dns.RR, bool) {
+	z.mu.Lock()
+	mtime := z.mtime
+	journal := append([]journalEntry(nil), z.journal...)
+	z.mu.Unlock()
+
+	if uint32(mtime) == clientSerial {
+		// client already current: RFC 1995 §4 empty-delta response
+		return []dns.RR{z.soaRRAt(mtime)}, true
+	}
+
+	idx := -1
+	for i, e := range journal {
+		if uint32(e.oldSerial) == clientSerial {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil, false
+	}
+
+	// RFC 1995 §4: current SOA first, then per-delta (old SOA, removed,
+	// new SOA, added), then the current SOA again to close the transfer.
+	rr := []dns.RR{z.soaRRAt(mtime)}
+	for _, e := range journal[idx:] {
+		rr = append(rr, z.soaRRAt(e.oldSerial))
+		for _, v := range e.removed {
+			if tmp, err := dns.NewRR(v); err == nil {
+				rr = append(rr, tmp)
+			}
+		}
+		rr = append(rr, z.soaRRAt(e.newSerial))
+		for _, v := range e.added {
+			if tmp, err := dns.NewRR(v); err == nil {
+				rr = append(rr, tmp)
+			}
+		}
+	}
+	rr = append(rr, z.soaRRAt(mtime))
+
+	return rr, true
+}
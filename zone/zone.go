@@ -1,21 +1,19 @@
 package zone
 
 import (
-	"bufio"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"maps"
 	"net"
 	"net/netip"
 	"os"
 	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/miekg/dns"
+	"github.com/yseto/dynamic-dns-go/config"
 	"github.com/yseto/dynamic-dns-go/record"
 )
 
@@ -24,88 +22,120 @@ type Zone struct {
 	nsName    string
 	localAddr string
 
-	filename string
-	mtime    int64
-
-	records map[string][]string
+	backend record.Backend
+	mtime   int64
 
 	mu sync.Mutex
 
 	allowCIDR string
-}
 
-type Dic struct {
-	Domain  string   `json:"domain"`
-	Records []string `json:"records"`
+	signer *dnssecSigner
+
+	notifyTargets []string
+	journal       []journalEntry
+
+	answerOrder string
+	rrCounters  map[string]uint64
 }
 
-func New(zoneName, nsName, filename, localAddr, allowCIDR string) (*Zone, error) {
-	st, err := os.Stat(filename)
+// New builds a Zone backed by the store named by zc.DBFile's scheme:
+//   - "sqlite://path"  -> SQLite-backed store at path
+//   - "mem://"         -> pure in-memory store, discarded on exit
+//   - anything else    -> the original JSON-lines file store
+//
+// If zc.DNSSEC is non-nil, answers are online-signed with the referenced
+// KSK/ZSK keypair whenever the client sets EDNS0 DO. If zc.NotifyTargets is
+// non-empty, each successful UPDATE sends those secondaries a NOTIFY and
+// records a journal entry so they can IXFR the delta instead of a full AXFR.
+// zc.AnswerOrder selects how multi-value RRsets are ordered in answers (see
+// reorderAnswers).
+func New(zc config.ConfZone, localAddr string) (*Zone, error) {
+	backend, mtime, err := newBackend(zc.DBFile)
 	if err != nil {
 		return nil, err
 	}
 
+	var signer *dnssecSigner
+	if zc.DNSSEC != nil {
+		signer, err = newDNSSECSigner(zc.DNSSEC)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Zone{
-		zoneName:  zoneName,
-		nsName:    nsName,
+		zoneName:  zc.ZoneName,
+		nsName:    zc.NsName,
 		localAddr: localAddr,
 
-		filename: filename,
-		mtime:    st.ModTime().Unix(),
+		backend: backend,
+		mtime:   mtime,
 
-		records: map[string][]string{},
+		allowCIDR: zc.AllowCIDR,
 
-		allowCIDR: allowCIDR,
+		signer: signer,
+
+		notifyTargets: zc.NotifyTargets,
+
+		answerOrder: zc.AnswerOrder,
+		rrCounters:  map[string]uint64{},
 	}, nil
 }
 
-func (z *Zone) ReadDB() error {
-	f, err := os.Open(z.filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	dec := json.NewDecoder(f)
-	for {
-		var v Dic
-		if err := dec.Decode(&v); err == io.EOF {
-			break // done decoding file
-		} else if err != nil {
-			return err
+func newBackend(dbFile string) (record.Backend, int64, error) {
+	switch {
+	case strings.HasPrefix(dbFile, "sqlite://"):
+		b, err := newSqliteBackend(strings.TrimPrefix(dbFile, "sqlite://"))
+		if err != nil {
+			return nil, 0, err
 		}
-		z.records[v.Domain] = v.Records
+		return b, time.Now().Unix(), nil
+	case strings.HasPrefix(dbFile, "mem://"):
+		return newMemBackend(), time.Now().Unix(), nil
+	default:
+		st, err := os.Stat(dbFile)
+		if err != nil {
+			return nil, 0, err
+		}
+		return newFileBackend(dbFile), st.ModTime().Unix(), nil
 	}
-	return nil
 }
 
-func (z *Zone) writeDB(content map[string][]string) error {
-	f, err := os.Create(z.filename)
-	if err != nil {
-		return err
-	}
+func (z *Zone) ReadDB() error {
+	return z.backend.Load()
+}
 
-	w := bufio.NewWriter(f)
-	for k, v := range content {
-		b, err := json.Marshal(Dic{Domain: k, Records: v})
-		if err != nil {
-			return err
-		}
-		if _, err := w.Write(append(b, '\n')); err != nil {
-			return err
+// Name returns the zone's apex name, e.g. "example.com.".
+func (z *Zone) Name() string {
+	return z.zoneName
+}
+
+// Records returns every RR currently stored in the zone, for the admin API's
+// record listing and export endpoints.
+func (z *Zone) Records() ([]dns.RR, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	var rr []dns.RR
+	for _, values := range z.backend.Iterate() {
+		for _, v := range values {
+			tmp, err := dns.NewRR(v)
+			if err != nil {
+				return nil, err
+			}
+			rr = append(rr, tmp)
 		}
 	}
-	if err := w.Flush(); err != nil {
-		return err
-	}
-	if f.Close() != nil {
-		return err
-	}
-
-	z.records = maps.Clone(content)
-	z.mtime = time.Now().Unix()
+	return rr, nil
+}
 
-	return nil
+// Export returns the zone in AXFR order (bracketing SOA, NS/A glue, then
+// every record), suitable for rendering as a BIND-style zonefile.
+//
+// axfrRecord locks z.mu itself where it touches mutable zone state (via
+// soaRR), so Export doesn't take the lock around it.
+func (z *Zone) Export() ([]dns.RR, error) {
+	return z.axfrRecord()
 }
 
 func (z *Zone) getRecord(qName string, qQtype uint16) ([]dns.RR, error) {
@@ -117,9 +147,9 @@ func (z *Zone) getRecord(qName string, qQtype uint16) ([]dns.RR, error) {
 			return nil, err
 		}
 
-		for key := range z.records {
+		for key, values := range z.backend.Iterate() {
 			if strings.HasPrefix(key, prefix) {
-				matchedRecords = append(matchedRecords, z.records[key]...)
+				matchedRecords = append(matchedRecords, values...)
 			}
 		}
 	} else {
@@ -128,12 +158,17 @@ func (z *Zone) getRecord(qName string, qQtype uint16) ([]dns.RR, error) {
 		if err != nil {
 			return nil, err
 		}
-		matchedRecords = append(matchedRecords, z.records[key]...)
+		matchedRecords = append(matchedRecords, z.backend.Get(key)...)
 	}
 
 	tmpqName := strings.ToLower(qName)
+	counterKey := tmpqName + "_" + dns.Type(qQtype).String()
 
 	if len(matchedRecords) == 0 {
+		if rrs, ok := z.wildcardRecord(qName, qQtype); ok {
+			return z.reorderAnswers(counterKey, qQtype, rrs), nil
+		}
+
 		err := fmt.Errorf("Record not found, domain: %s, type: %s", tmpqName, dns.Type(qQtype).String())
 		slog.Error("Record not found", "Name", tmpqName, "Type", dns.Type(qQtype).String())
 		return nil, err
@@ -150,13 +185,115 @@ func (z *Zone) getRecord(qName string, qQtype uint16) ([]dns.RR, error) {
 			rr = append(rr, tmp)
 		}
 	}
-	return rr, nil
+	return z.reorderAnswers(counterKey, qQtype, rr), nil
+}
+
+// wildcardRecord implements RFC 1034 §4.3.3 wildcard lookup: when qName has
+// no exact-match records, walk its ancestors from the closest upward and
+// return the first "*.ancestor" RRset found, with its owner name rewritten
+// to qName. Per the closest-encloser rule, synthesis is skipped entirely if
+// qName already owns a subtree of concrete records (it's an empty
+// non-terminal, not an absent name).
+func (z *Zone) wildcardRecord(qName string, qQtype uint16) ([]dns.RR, bool) {
+	prefix, err := record.GetKeyDomain(qName)
+	if err != nil {
+		return nil, false
+	}
+	// prefix+"_" matches qName's own keys, prefix+"." those of names below it
+	if has, _ := z.backend.HasPrefix(prefix + "_"); has {
+		return nil, false
+	}
+	if has, _ := z.backend.HasPrefix(prefix + "."); has {
+		return nil, false
+	}
+
+	zoneApex := strings.ToLower(dns.Fqdn(z.zoneName))
+	labels := dns.SplitDomainName(qName)
+
+	for i := 1; i < len(labels); i++ {
+		ancestor := strings.ToLower(dns.Fqdn(strings.Join(labels[i:], ".")))
+		wildcard := "*." + ancestor
+
+		var values []string
+		if qQtype == dns.TypeANY {
+			wprefix, err := record.GetKeyDomain(wildcard)
+			if err == nil {
+				for key, v := range z.backend.Iterate() {
+					if strings.HasPrefix(key, wprefix) {
+						values = append(values, v...)
+					}
+				}
+			}
+		} else {
+			key, err := record.GetKey(wildcard, qQtype)
+			if err == nil {
+				values = z.backend.Get(key)
+			}
+		}
+
+		if len(values) > 0 {
+			rrs := make([]dns.RR, 0, len(values))
+			for _, v := range values {
+				tmp, err := dns.NewRR(v)
+				if err != nil {
+					return nil, false
+				}
+				tmp.Header().Name = qName
+				rrs = append(rrs, tmp)
+			}
+			return rrs, true
+		}
+
+		if ancestor == zoneApex {
+			break
+		}
+
+		// Closest-encloser rule: having failed to find a wildcard owned by
+		// this ancestor, stop climbing any further if the ancestor itself
+		// already owns a subtree of concrete records — qName then sits
+		// below a real node rather than an absent name, so no wildcard
+		// higher than here may apply.
+		if aprefix, err := record.GetKeyDomain(ancestor); err == nil {
+			if has, _ := z.backend.HasPrefix(aprefix + "_"); has {
+				return nil, false
+			}
+			if has, _ := z.backend.HasPrefix(aprefix + "."); has {
+				return nil, false
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// ownerIndex walks every stored record to build the sorted owner-name
+// keyspace NSEC denial-of-existence synthesis walks over.
+func (z *Zone) ownerIndex() ([]string, map[string][]uint16) {
+	typesAt := map[string][]uint16{}
+	for _, values := range z.backend.Iterate() {
+		for _, v := range values {
+			rr, err := dns.NewRR(v)
+			if err != nil {
+				continue
+			}
+			owner := strings.ToLower(rr.Header().Name)
+			typesAt[owner] = append(typesAt[owner], rr.Header().Rrtype)
+		}
+	}
+
+	owners := make([]string, 0, len(typesAt))
+	for o := range typesAt {
+		owners = append(owners, o)
+	}
+	sort.Strings(owners)
+
+	return owners, typesAt
 }
 
 func (z *Zone) axfrRecord() ([]dns.RR, error) {
 	var matchedRecords []string
-	for key := range z.records {
-		matchedRecords = append(matchedRecords, z.records[key]...)
+	for _, values := range z.backend.Iterate() {
+		matchedRecords = append(matchedRecords, values...)
 	}
 
 	var rr []dns.RR
@@ -193,6 +330,12 @@ func (z *Zone) HandleRequest(w dns.ResponseWriter, r *dns.Msg) {
 		invalidTsig = false
 	}
 
+	doSign := false
+	if opt := r.IsEdns0(); opt != nil {
+		m.SetEdns0(dns.DefaultMsgSize, opt.Do())
+		doSign = z.signer != nil && opt.Do()
+	}
+
 	switch r.Opcode {
 	case dns.OpcodeQuery:
 		for _, q := range m.Question {
@@ -211,6 +354,16 @@ func (z *Zone) HandleRequest(w dns.ResponseWriter, r *dns.Msg) {
 				continue
 			}
 
+			if qZone && q.Qtype == dns.TypeDNSKEY && z.signer != nil {
+				m.Answer = append(m.Answer, z.signer.dnskeyRRset()...)
+				continue
+			}
+
+			if qZone && q.Qtype == dns.TypeDS && z.signer != nil {
+				m.Answer = append(m.Answer, z.signer.ksk.ToDS(dns.SHA256))
+				continue
+			}
+
 			// AXFR 転送は、TSIGを必要とする
 			// CNAME 判定、個別のタイプによる判定の前に入れる
 			if qZone && q.Qtype == dns.TypeAXFR {
@@ -227,6 +380,32 @@ func (z *Zone) HandleRequest(w dns.ResponseWriter, r *dns.Msg) {
 				continue
 			}
 
+			// IXFR も AXFR 同様 TSIG を必要とする
+			if qZone && q.Qtype == dns.TypeIXFR {
+				if invalidTsig {
+					slog.Warn("TSIG Error")
+					m.Rcode = dns.RcodeNotAuth
+					return
+				}
+				m.Authoritative = true
+
+				served := false
+				if clientSerial, ok := ixfrClientSerial(r); ok {
+					if rrs, ok := z.ixfrRecord(clientSerial); ok {
+						m.Answer = rrs
+						served = true
+					}
+				}
+				if !served {
+					// no usable client serial, or the journal doesn't reach
+					// back that far: fall back to a full AXFR (RFC 1995 §2).
+					if rrs, e := z.axfrRecord(); e == nil {
+						m.Answer = rrs
+					}
+				}
+				continue
+			}
+
 			if rrs, e := z.getRecord(q.Name, q.Qtype); e == nil {
 				m.Answer = append(m.Answer, rrs...)
 				continue
@@ -278,12 +457,30 @@ func (z *Zone) HandleRequest(w dns.ResponseWriter, r *dns.Msg) {
 					continue
 				}
 			}
+
+			// 何も見つからなかった場合、DNSSEC 要求であれば否定応答を NSEC で証明する
+			if doSign {
+				owners, typesAt := z.ownerIndex()
+				nsecRR := nsec(q.Name, strings.ToLower(z.zoneName), owners, func(o string) []uint16 {
+					return typesAt[o]
+				})
+				m.Ns = append(m.Ns, nsecRR)
+
+				// The NSEC's owner matches qName exactly only for NODATA
+				// (the name exists, just not with this qtype); any other
+				// owner means qName itself doesn't exist, i.e. NXDOMAIN.
+				if !strings.EqualFold(nsecRR.Header().Name, q.Name) {
+					m.Rcode = dns.RcodeNameError
+				}
+			}
 		}
 
-	case dns.OpcodeUpdate:
-		z.mu.Lock()
-		defer z.mu.Unlock()
+		if doSign {
+			m.Answer = z.signer.signRRsets(m.Answer, strings.ToLower(z.zoneName))
+			m.Ns = z.signer.signRRsets(m.Ns, strings.ToLower(z.zoneName))
+		}
 
+	case dns.OpcodeUpdate:
 		if z.allowCIDR != "" {
 			prefix := netip.MustParsePrefix(z.allowCIDR)
 
@@ -301,31 +498,75 @@ func (z *Zone) HandleRequest(w dns.ResponseWriter, r *dns.Msg) {
 			return
 		}
 
-		// padで作業する
-		pad := record.NewPad(z.records)
-
 		for _, question := range r.Question {
-			for _, rr := range r.Ns {
-				if err := pad.UpdateRecord(rr, &question); err != nil {
-					slog.Error("Failed updateRecord", "detail", err.Error())
-					m.Rcode = dns.RcodeRefused
-					return
-				}
+			if err := z.ApplyUpdate(question, r.Ns); err != nil {
+				slog.Error("Failed updateRecord", "detail", err.Error())
+				m.Rcode = dns.RcodeRefused
+				return
 			}
 		}
 
-		if err := z.writeDB(pad.Records()); err != nil {
-			slog.Error("writeDB", "detail", err.Error())
-			m.Rcode = dns.RcodeRefused
-			return
+		slog.Info("updateed")
+	}
+}
+
+// ApplyUpdate applies RFC 2136 update RRs to the zone under z.mu, journaling
+// the resulting delta and notifying secondaries exactly as a DNS UPDATE
+// request would. It's the shared path behind both the UPDATE opcode handler
+// and the admin HTTP API, so both leave the backend in the same state.
+func (z *Zone) ApplyUpdate(question dns.Question, rrs []dns.RR) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	// backend 上で直接作業する。journalRecorder を挟んで IXFR 用の差分を取る
+	rec := newJournalRecorder(z.backend)
+	pad := record.NewPad(rec)
+
+	for _, rr := range rrs {
+		if err := pad.UpdateRecord(rr, &question); err != nil {
+			return err
 		}
+	}
 
-		slog.Info("updateed")
+	if err := z.backend.Flush(); err != nil {
+		return err
+	}
+
+	oldSerial := z.mtime
+	z.mtime = nextSerial(z.mtime)
+
+	added, removed := rec.diff()
+	z.appendJournal(oldSerial, z.mtime, added, removed)
+	z.notifySecondaries()
+
+	return nil
+}
+
+// nextSerial returns a serial strictly greater than prev. Wall-clock time is
+// only 1-second resolution, so two updates landing within the same second
+// would otherwise produce oldSerial == newSerial: the journal entry is still
+// recorded, but a secondary polling IXFR with that unchanged serial would
+// hit the "client already current" shortcut and silently miss the change.
+func nextSerial(prev int64) int64 {
+	now := time.Now().Unix()
+	if now > prev {
+		return now
 	}
+	return prev + 1
 }
 
+// soaRR builds the current SOA RR. z.mtime is also written by ApplyUpdate
+// under z.mu, so the read here takes the same lock rather than relying on
+// the caller to hold it.
 func (z *Zone) soaRR() dns.RR {
-	soaRR, _ := dns.NewRR(fmt.Sprintf("%s 3600 IN SOA localhost. nobody.  %d 28800 7200 2419200 1200", z.zoneName, int32(z.mtime)))
+	z.mu.Lock()
+	serial := z.mtime
+	z.mu.Unlock()
+	return z.soaRRAt(serial)
+}
+
+func (z *Zone) soaRRAt(serial int64) dns.RR {
+	soaRR, _ := dns.NewRR(fmt.Sprintf("%s 3600 IN SOA localhost. nobody.  %d 28800 7200 2419200 1200", z.zoneName, int32(serial)))
 	return soaRR
 }
 
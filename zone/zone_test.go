@@ -0,0 +1,77 @@
+package zone
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/yseto/dynamic-dns-go/record"
+)
+
+func newTestZone(t *testing.T, zoneName string, rrs ...string) *Zone {
+	t.Helper()
+
+	backend := newMemBackend()
+	z := &Zone{zoneName: zoneName, backend: backend}
+
+	for _, v := range rrs {
+		rr, err := dns.NewRR(v)
+		if err != nil {
+			t.Fatalf("parsing test RR %q: %v", v, err)
+		}
+		key, err := record.GetKey(rr.Header().Name, rr.Header().Rrtype)
+		if err != nil {
+			t.Fatalf("keying test RR %q: %v", v, err)
+		}
+		if err := backend.Put(key, append(backend.Get(key), v)); err != nil {
+			t.Fatalf("storing test RR %q: %v", v, err)
+		}
+	}
+
+	return z
+}
+
+func TestWildcardRecordMatchesAboveAncestorWithConcreteRecords(t *testing.T) {
+	z := newTestZone(t, "example.com.",
+		"parent.example.com. 3600 IN A 1.1.1.1",
+		"*.parent.example.com. 3600 IN A 2.2.2.2",
+	)
+
+	rrs, ok := z.wildcardRecord("foo.parent.example.com.", dns.TypeA)
+	if !ok {
+		t.Fatal("expected wildcard match for foo.parent.example.com., got none")
+	}
+	if len(rrs) != 1 {
+		t.Fatalf("expected 1 RR, got %d", len(rrs))
+	}
+	a, ok := rrs[0].(*dns.A)
+	if !ok || a.A.String() != "2.2.2.2" {
+		t.Fatalf("expected A 2.2.2.2, got %v", rrs[0])
+	}
+	if rrs[0].Header().Name != "foo.parent.example.com." {
+		t.Fatalf("expected owner rewritten to qName, got %q", rrs[0].Header().Name)
+	}
+}
+
+func TestWildcardRecordClosestEncloserBlocksHigherWildcard(t *testing.T) {
+	z := newTestZone(t, "example.com.",
+		"bar.example.com. 3600 IN A 1.1.1.1",
+		"*.example.com. 3600 IN A 2.2.2.2",
+	)
+
+	// bar.example.com. has its own concrete record but no data of the
+	// queried subtree, so foo.bar.example.com. sits below a real node and
+	// must not be answered from the *.example.com. wildcard.
+	_, ok := z.wildcardRecord("foo.bar.example.com.", dns.TypeA)
+	if ok {
+		t.Fatal("expected no wildcard match below a concrete-record ancestor")
+	}
+}
+
+func TestWildcardRecordNoMatch(t *testing.T) {
+	z := newTestZone(t, "example.com.", "other.example.com. 3600 IN A 1.1.1.1")
+
+	_, ok := z.wildcardRecord("missing.example.com.", dns.TypeA)
+	if ok {
+		t.Fatal("expected no wildcard match when none is stored")
+	}
+}